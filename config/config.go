@@ -0,0 +1,31 @@
+// Package config holds site-wide settings that would otherwise be hardcoded
+// across the generator, feeds, and runtime server.
+package config
+
+import "time"
+
+// Config describes the site as a whole: where it's hosted, who writes it,
+// and when it started, so that generated URLs and feed metadata stay
+// consistent wherever they're produced.
+type Config struct {
+	// Domain is the bare hostname, used for tag URIs (RFC 4151).
+	Domain string
+	// BaseURL is the canonical origin, including scheme, used for links.
+	BaseURL string
+	// AuthorName and AuthorEmail identify the feed/site author.
+	AuthorName  string
+	AuthorEmail string
+	// StartDate anchors tag URIs for entries that predate per-post dating.
+	StartDate time.Time
+}
+
+// Default returns the configuration for mbaykara.github.io.
+func Default() Config {
+	return Config{
+		Domain:      "mbaykara.github.io",
+		BaseURL:     "https://mbaykara.github.io",
+		AuthorName:  "Mustafa Baykara",
+		AuthorEmail: "hello@mbaykara.github.io",
+		StartDate:   time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+	}
+}
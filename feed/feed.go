@@ -0,0 +1,161 @@
+// Package feed builds Atom 1.0 and RSS 2.0 feed documents from a site's
+// posts, independent of whether they're served at runtime or written to
+// disk by a static generator.
+package feed
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"time"
+)
+
+// FeedEntry is a single entry shared by both the Atom and RSS writers.
+type FeedEntry struct {
+	Title     string
+	Link      string // canonical URL of the entry's HTML page
+	ID        string // stable tag URI, see MakeTagURI
+	Published time.Time
+	Updated   time.Time
+	Summary   string // first paragraph, HTML
+	Content   string // full entry content, HTML
+}
+
+// FeedContent describes a complete feed document: its own metadata plus
+// the entries it carries.
+type FeedContent struct {
+	Title   string
+	Link    string // HTML page this feed is the feed of
+	Self    string // canonical URL of the feed document itself
+	Author  string
+	Updated time.Time
+	Entries []FeedEntry
+}
+
+// MakeTagURI builds an RFC 4151 tag URI, e.g.
+// tag:example.com,2024-01-01:post/slug. startDate should be the date
+// the specific resource was created, formatted as YYYY-MM-DD.
+func MakeTagURI(domain, startDate, specific string) string {
+	return fmt.Sprintf("tag:%s,%s:%s", domain, startDate, specific)
+}
+
+// atom* types mirror the subset of the Atom 1.0 schema (RFC 4287) this
+// package emits.
+type atomFeed struct {
+	XMLName xml.Name    `xml:"http://www.w3.org/2005/Atom feed"`
+	Title   string      `xml:"title"`
+	ID      string      `xml:"id"`
+	Updated string      `xml:"updated"`
+	Author  atomPerson  `xml:"author"`
+	Links   []atomLink  `xml:"link"`
+	Entries []atomEntry `xml:"entry"`
+}
+
+type atomPerson struct {
+	Name string `xml:"name"`
+}
+
+type atomLink struct {
+	Rel  string `xml:"rel,attr,omitempty"`
+	Href string `xml:"href,attr"`
+	Type string `xml:"type,attr,omitempty"`
+}
+
+type atomEntry struct {
+	Title     string   `xml:"title"`
+	ID        string   `xml:"id"`
+	Link      atomLink `xml:"link"`
+	Published string   `xml:"published"`
+	Updated   string   `xml:"updated"`
+	Summary   atomText `xml:"summary"`
+	Content   atomText `xml:"content"`
+}
+
+type atomText struct {
+	Type  string `xml:"type,attr"`
+	Value string `xml:",chardata"`
+}
+
+// WriteAtom renders f as an Atom 1.0 feed.
+func (f FeedContent) WriteAtom(w io.Writer) error {
+	doc := atomFeed{
+		Title:   f.Title,
+		ID:      f.Self,
+		Updated: f.Updated.Format(time.RFC3339),
+		Author:  atomPerson{Name: f.Author},
+		Links: []atomLink{
+			{Rel: "alternate", Href: f.Link, Type: "text/html"},
+			{Rel: "self", Href: f.Self, Type: "application/atom+xml"},
+		},
+	}
+	for _, e := range f.Entries {
+		doc.Entries = append(doc.Entries, atomEntry{
+			Title:     e.Title,
+			ID:        e.ID,
+			Link:      atomLink{Rel: "alternate", Href: e.Link, Type: "text/html"},
+			Published: e.Published.Format(time.RFC3339),
+			Updated:   e.Updated.Format(time.RFC3339),
+			Summary:   atomText{Type: "html", Value: e.Summary},
+			Content:   atomText{Type: "html", Value: e.Content},
+		})
+	}
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	return enc.Encode(doc)
+}
+
+// rss* types mirror the subset of the RSS 2.0 schema this package emits.
+type rssFeed struct {
+	XMLName xml.Name   `xml:"rss"`
+	Version string     `xml:"version,attr"`
+	Channel rssChannel `xml:"channel"`
+}
+
+type rssChannel struct {
+	Title         string    `xml:"title"`
+	Link          string    `xml:"link"`
+	Description   string    `xml:"description"`
+	LastBuildDate string    `xml:"lastBuildDate"`
+	Items         []rssItem `xml:"item"`
+}
+
+type rssItem struct {
+	Title       string `xml:"title"`
+	Link        string `xml:"link"`
+	GUID        string `xml:"guid"`
+	PubDate     string `xml:"pubDate"`
+	Description string `xml:"description"`
+}
+
+// WriteRSS renders f as an RSS 2.0 feed.
+func (f FeedContent) WriteRSS(w io.Writer) error {
+	doc := rssFeed{
+		Version: "2.0",
+		Channel: rssChannel{
+			Title:         f.Title,
+			Link:          f.Link,
+			Description:   f.Title,
+			LastBuildDate: f.Updated.Format(time.RFC1123Z),
+		},
+	}
+	for _, e := range f.Entries {
+		doc.Channel.Items = append(doc.Channel.Items, rssItem{
+			Title:       e.Title,
+			Link:        e.Link,
+			GUID:        e.ID,
+			PubDate:     e.Published.Format(time.RFC1123Z),
+			Description: e.Summary,
+		})
+	}
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	return enc.Encode(doc)
+}
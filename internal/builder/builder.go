@@ -0,0 +1,155 @@
+// Package builder content-hashes static assets and rewrites references to
+// them in generated HTML, producing both an asset manifest and the
+// Content-Security-Policy value that manifest implies.
+package builder
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+// Asset is one file from the static directory after content hashing.
+type Asset struct {
+	// OriginalPath is the path relative to the static directory, e.g. "style.css".
+	OriginalPath string
+	// HashedPath is OriginalPath with the content hash spliced in, e.g. "style.abc12345.css".
+	HashedPath string
+	// Hash is the full sha256 hex digest of the file's contents.
+	Hash string
+}
+
+// Manifest maps an asset's original relative path to its hashed form.
+type Manifest map[string]Asset
+
+// Build walks staticDir, copies every file into outDir under a
+// content-hashed name, and returns the resulting manifest.
+func Build(staticDir, outDir string) (Manifest, error) {
+	manifest := Manifest{}
+
+	err := filepath.Walk(staticDir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() {
+			return nil
+		}
+
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+
+		relPath, err := filepath.Rel(staticDir, path)
+		if err != nil {
+			return err
+		}
+
+		sum := sha256.Sum256(data)
+		hash := hex.EncodeToString(sum[:])
+
+		ext := filepath.Ext(relPath)
+		base := strings.TrimSuffix(relPath, ext)
+		hashedRel := fmt.Sprintf("%s.%s%s", base, hash[:8], ext)
+
+		outPath := filepath.Join(outDir, hashedRel)
+		if err := os.MkdirAll(filepath.Dir(outPath), 0755); err != nil {
+			return err
+		}
+		if err := os.WriteFile(outPath, data, 0644); err != nil {
+			return err
+		}
+
+		manifest[relPath] = Asset{
+			OriginalPath: relPath,
+			HashedPath:   hashedRel,
+			Hash:         hash,
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return manifest, nil
+}
+
+// CSP is the set of content hashes a page's Content-Security-Policy needs
+// to allow its own inline scripts and styles.
+type CSP struct {
+	ScriptHashes []string // "sha256-<base64>" per inline <script> block
+	StyleHashes  []string // "sha256-<base64>" per inline <style> block
+}
+
+// Header renders c as a Content-Security-Policy header value.
+func (c CSP) Header() string {
+	scriptSrc := []string{"'self'"}
+	for _, h := range c.ScriptHashes {
+		scriptSrc = append(scriptSrc, "'"+h+"'")
+	}
+	styleSrc := []string{"'self'"}
+	for _, h := range c.StyleHashes {
+		styleSrc = append(styleSrc, "'"+h+"'")
+	}
+	return fmt.Sprintf("default-src 'self'; script-src %s; style-src %s",
+		strings.Join(scriptSrc, " "), strings.Join(styleSrc, " "))
+}
+
+// RewriteHTML rewrites references to manifest assets (in link[href],
+// script[src], and img[src]) to their hashed paths, and returns the CSP
+// hashes for any inline <script>/<style> blocks it finds along the way.
+func RewriteHTML(html []byte, manifest Manifest, staticPrefix string) ([]byte, CSP, error) {
+	doc, err := goquery.NewDocumentFromReader(bytes.NewReader(html))
+	if err != nil {
+		return nil, CSP{}, err
+	}
+
+	rewrite := func(i int, sel *goquery.Selection, attr string) {
+		val, ok := sel.Attr(attr)
+		if !ok || !strings.HasPrefix(val, staticPrefix) {
+			return
+		}
+		rel := strings.TrimPrefix(val, staticPrefix)
+		if asset, ok := manifest[rel]; ok {
+			sel.SetAttr(attr, staticPrefix+asset.HashedPath)
+		}
+	}
+	doc.Find("link[href]").Each(func(i int, s *goquery.Selection) { rewrite(i, s, "href") })
+	doc.Find("script[src]").Each(func(i int, s *goquery.Selection) { rewrite(i, s, "src") })
+	doc.Find("img[src]").Each(func(i int, s *goquery.Selection) { rewrite(i, s, "src") })
+
+	var csp CSP
+	doc.Find("script:not([src])").Each(func(i int, s *goquery.Selection) {
+		csp.ScriptHashes = append(csp.ScriptHashes, hashBlock(s.Text()))
+	})
+	doc.Find("style").Each(func(i int, s *goquery.Selection) {
+		csp.StyleHashes = append(csp.StyleHashes, hashBlock(s.Text()))
+	})
+
+	out, err := doc.Html()
+	if err != nil {
+		return nil, CSP{}, err
+	}
+	return []byte(out), csp, nil
+}
+
+// hashBlock returns the sha256 CSP source expression for an inline block's content.
+func hashBlock(content string) string {
+	sum := sha256.Sum256([]byte(content))
+	return "sha256-" + base64.StdEncoding.EncodeToString(sum[:])
+}
+
+// WriteHeadersFile writes a Netlify/CloudFront-style _headers file applying
+// csp to every path.
+func WriteHeadersFile(w io.Writer, csp string) error {
+	_, err := fmt.Fprintf(w, "/*\n  Content-Security-Policy: %s\n", csp)
+	return err
+}
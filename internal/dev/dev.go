@@ -0,0 +1,259 @@
+// Package dev implements a live-reloading development server: it watches
+// the site's content and template directories, rebuilds on change, and
+// nudges connected browsers to refresh over server-sent events.
+package dev
+
+import (
+	"bytes"
+	"context"
+	"log"
+	"net/http"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// Config controls how the dev server builds and serves the site.
+type Config struct {
+	// Addr is the address to listen on, e.g. ":8090".
+	Addr string
+	// Watch lists the directories whose changes should trigger a rebuild.
+	Watch []string
+	// Build produces the handler that serves the current state of the
+	// site. It's called once at startup and again after every change to
+	// a watched directory.
+	Build func() (http.Handler, error)
+}
+
+const reloadScript = `<script>(function(){
+	var overlay;
+	function showError(msg){
+		if(!overlay){
+			overlay=document.createElement("pre");
+			overlay.style.cssText="position:fixed;bottom:0;left:0;right:0;max-height:50%;margin:0;padding:1em;overflow:auto;background:#300;color:#fff;font:12px monospace;white-space:pre-wrap;z-index:2147483647";
+			document.body.appendChild(overlay);
+		}
+		overlay.textContent=msg;
+		overlay.style.display="block";
+	}
+	function hideError(){
+		if(overlay){overlay.style.display="none";}
+	}
+	var s=new EventSource("/_dev/events");
+	s.onmessage=function(e){
+		if(e.data==="reload"){hideError();location.reload();}
+		else if(e.data.indexOf("error: ")===0){showError(e.data.slice(7));}
+	};
+})();</script>`
+
+// Serve builds the site, starts an HTTP server with live reload wired in,
+// and blocks until it receives SIGINT, at which point it shuts down
+// gracefully.
+func Serve(cfg Config) error {
+	srv := &server{build: cfg.Build, events: newBroadcaster()}
+	if err := srv.rebuild(); err != nil {
+		return err
+	}
+
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return err
+	}
+	defer watcher.Close()
+	for _, dir := range cfg.Watch {
+		if err := watcher.Add(dir); err != nil {
+			return err
+		}
+	}
+	go srv.watch(watcher)
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/_dev/events", srv.events.handle)
+	mux.Handle("/", srv)
+
+	httpSrv := &http.Server{Addr: cfg.Addr, Handler: mux}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	errCh := make(chan error, 1)
+	go func() {
+		log.Printf("dev server listening on %s", cfg.Addr)
+		if err := httpSrv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			errCh <- err
+		}
+	}()
+
+	select {
+	case <-ctx.Done():
+	case err := <-errCh:
+		return err
+	}
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	return httpSrv.Shutdown(shutdownCtx)
+}
+
+// server serves the most recently built handler, injecting a live-reload
+// script into HTML responses. Rebuilds take an exclusive lock so that
+// in-flight requests block briefly instead of racing a half-built site.
+type server struct {
+	build func() (http.Handler, error)
+
+	mu      sync.RWMutex
+	handler http.Handler
+
+	events *broadcaster
+}
+
+func (s *server) rebuild() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	h, err := s.build()
+	if err != nil {
+		return err
+	}
+	s.handler = h
+	return nil
+}
+
+func (s *server) watch(watcher *fsnotify.Watcher) {
+	for {
+		select {
+		case ev, ok := <-watcher.Events:
+			if !ok {
+				return
+			}
+			if ev.Op&(fsnotify.Write|fsnotify.Create|fsnotify.Remove|fsnotify.Rename) == 0 {
+				continue
+			}
+			if err := s.rebuild(); err != nil {
+				log.Printf("dev: build failed: %v", err)
+				s.events.publish("error: " + err.Error())
+				continue
+			}
+			s.events.publish("reload")
+		case err, ok := <-watcher.Errors:
+			if !ok {
+				return
+			}
+			log.Printf("dev: watcher error: %v", err)
+		}
+	}
+}
+
+func (s *server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.RLock()
+	h := s.handler
+	s.mu.RUnlock()
+
+	rec := &recorder{status: http.StatusOK}
+	h.ServeHTTP(rec, r)
+
+	body := rec.buf.Bytes()
+	if isHTML(rec.Header()) {
+		body = injectReloadScript(body)
+	}
+	for k, v := range rec.Header() {
+		w.Header()[k] = v
+	}
+	w.WriteHeader(rec.status)
+	w.Write(body)
+}
+
+func isHTML(h http.Header) bool {
+	ct := h.Get("Content-Type")
+	return ct == "" || bytes.Contains([]byte(ct), []byte("html"))
+}
+
+func injectReloadScript(body []byte) []byte {
+	const tag = "</body>"
+	script := []byte(reloadScript)
+	idx := bytes.LastIndex(body, []byte(tag))
+	if idx == -1 {
+		return append(body, script...)
+	}
+	out := make([]byte, 0, len(body)+len(script))
+	out = append(out, body[:idx]...)
+	out = append(out, script...)
+	out = append(out, body[idx:]...)
+	return out
+}
+
+// recorder is a minimal http.ResponseWriter that buffers a response so it
+// can be post-processed before being written to the real client.
+type recorder struct {
+	header http.Header
+	status int
+	buf    bytes.Buffer
+}
+
+func (r *recorder) Header() http.Header {
+	if r.header == nil {
+		r.header = make(http.Header)
+	}
+	return r.header
+}
+
+func (r *recorder) Write(b []byte) (int, error) { return r.buf.Write(b) }
+
+func (r *recorder) WriteHeader(status int) { r.status = status }
+
+// broadcaster fans out build events to connected /_dev/events clients.
+type broadcaster struct {
+	mu      sync.Mutex
+	clients map[chan string]struct{}
+}
+
+func newBroadcaster() *broadcaster {
+	return &broadcaster{clients: make(map[chan string]struct{})}
+}
+
+func (b *broadcaster) publish(event string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for ch := range b.clients {
+		select {
+		case ch <- event:
+		default:
+		}
+	}
+}
+
+func (b *broadcaster) handle(w http.ResponseWriter, r *http.Request) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "streaming unsupported", http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+
+	ch := make(chan string, 1)
+	b.mu.Lock()
+	b.clients[ch] = struct{}{}
+	b.mu.Unlock()
+	defer func() {
+		b.mu.Lock()
+		delete(b.clients, ch)
+		b.mu.Unlock()
+	}()
+
+	for {
+		select {
+		case event := <-ch:
+			if _, err := w.Write([]byte("data: " + event + "\n\n")); err != nil {
+				return
+			}
+			flusher.Flush()
+		case <-r.Context().Done():
+			return
+		}
+	}
+}
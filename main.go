@@ -2,12 +2,17 @@ package main
 
 import (
 	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/xml"
 	"fmt"
 	"html/template"
+	"io"
+	"io/fs"
 	"log"
 	"net/http"
-	"net/url"
 	"os"
+	"path"
 	"path/filepath"
 	"sort"
 	"strings"
@@ -18,20 +23,88 @@ import (
 	highlighting "github.com/yuin/goldmark-highlighting/v2"
 	"golang.org/x/text/cases"
 	"golang.org/x/text/language"
+
+	"goweb/config"
+	"goweb/feed"
+	"goweb/internal/builder"
+	"goweb/internal/dev"
 )
 
+// runtimeManifest holds the content-hashed asset manifest built from
+// static/ at server startup, used by cspMiddleware to rewrite asset
+// references and compute the Content-Security-Policy header.
+var runtimeManifest builder.Manifest
+
+// siteConfig holds the settings shared by the feed, sitemap, and generator
+// code paths.
+var siteConfig = config.Default()
+
+// Site bundles the filesystems content and templates are read from, so
+// the same loading and rendering code works whether it's backed by disk
+// (the default, and what --dev watches) or an embed.FS baked into the
+// binary (see site_embed.go, built with -tags embedded).
+type Site struct {
+	ContentFS  fs.FS // posts/, thoughts/, nav/
+	TemplateFS fs.FS // templates/*.gohtml
+	StaticFS   fs.FS // static/*
+}
+
+// currentSite is the filesystem view used by the runtime server and the
+// static generator.
+var currentSite = NewSite()
+
 // PostFrontmatter represents the frontmatter data in markdown files.
 type PostFrontmatter struct {
-	Title string    `yaml:"title"`
-	Date  time.Time `yaml:"date"`
+	Title      string    `yaml:"title"`
+	Date       time.Time `yaml:"date"`
+	Tags       []string  `yaml:"tags"`
+	Categories []string  `yaml:"categories"`
+	Draft      bool      `yaml:"draft"`
 }
 
 // Post represents a blog post with a title, date, and content.
 type PostData struct {
-	Title   string
-	Date    time.Time
-	Slug    string
-	Content template.HTML // Content after converting from Markdown
+	Title      string
+	Date       time.Time
+	Slug       string
+	Content    template.HTML // Content after converting from Markdown
+	Tags       []string
+	Categories []string
+	Draft      bool
+	// Published is computed from Draft and Date against LoadOptions.Now:
+	// a post is published once it's neither a draft nor dated in the future.
+	Published bool
+}
+
+// LoadOptions controls which posts LoadPostFromFile and
+// loadPostsFromDirectory make visible.
+type LoadOptions struct {
+	// IncludeDrafts reveals draft and future-dated posts.
+	IncludeDrafts bool
+	// Now is the instant "future-dated" is evaluated against.
+	Now time.Time
+}
+
+// DefaultLoadOptions excludes drafts and future-dated posts, evaluated
+// against the current time.
+func DefaultLoadOptions() LoadOptions {
+	return LoadOptions{Now: time.Now()}
+}
+
+// previewTokenEnv names the environment variable a runtime request's
+// ?preview= query must match to reveal drafts.
+const previewTokenEnv = "PREVIEW_TOKEN"
+
+// loadOptionsForRequest grants draft visibility to a runtime request only
+// when its ?preview= query matches PREVIEW_TOKEN.
+func loadOptionsForRequest(r *http.Request) LoadOptions {
+	opts := DefaultLoadOptions()
+	if token := os.Getenv(previewTokenEnv); token != "" && r != nil && r.URL != nil {
+		if r.URL.Query().Get("preview") == token {
+			opts.IncludeDrafts = true
+		}
+	}
+	return opts
 }
 
 // TemplateData holds the data passed to the template.
@@ -40,9 +113,11 @@ type TemplateData struct {
 	Posts []PostData
 }
 
-// LoadPostFromFile loads a post from a file, parsing frontmatter for title and date.
-func LoadPostFromFile(filePath string) (PostData, error) {
-	mdBytes, err := os.ReadFile(filePath)
+// LoadPostFromFile loads a post from filePath in site.ContentFS, parsing
+// frontmatter for title, date, tags, and draft status, and computes
+// Published against opts.Now.
+func LoadPostFromFile(site Site, filePath string, opts LoadOptions) (PostData, error) {
+	mdBytes, err := fs.ReadFile(site.ContentFS, filePath)
 	if err != nil {
 		return PostData{}, err
 	}
@@ -57,14 +132,14 @@ func LoadPostFromFile(filePath string) (PostData, error) {
 	}
 
 	// Get file info for fallback date
-	fileInfo, err := os.Stat(filePath)
+	fileInfo, err := fs.Stat(site.ContentFS, filePath)
 	if err != nil {
 		return PostData{}, err
 	}
 
 	// Extract slug from filename
-	filename := filepath.Base(filePath)
-	slug := strings.TrimSuffix(filename, filepath.Ext(filename))
+	filename := path.Base(filePath)
+	slug := strings.TrimSuffix(filename, path.Ext(filename))
 
 	// Determine title: use frontmatter title, or cleaned filename
 	title := fm.Title
@@ -93,16 +168,21 @@ func LoadPostFromFile(filePath string) (PostData, error) {
 	}
 
 	return PostData{
-		Title:   title,
-		Date:    date,
-		Slug:    slug,
-		Content: template.HTML(buf.String()),
+		Title:      title,
+		Date:       date,
+		Slug:       slug,
+		Content:    template.HTML(buf.String()),
+		Tags:       fm.Tags,
+		Categories: fm.Categories,
+		Draft:      fm.Draft,
+		Published:  !fm.Draft && !date.After(opts.Now),
 	}, nil
 }
 
-// RenderMarkdown converts Markdown content to HTML (kept for backward compatibility).
-func RenderMarkdown(filePath string) (template.HTML, error) {
-	mdBytes, err := os.ReadFile(filePath)
+// renderMarkdownFS is RenderMarkdown read through an fs.FS instead of the
+// OS filesystem directly, for pages (like About) backed by site.ContentFS.
+func renderMarkdownFS(fsys fs.FS, filePath string) (template.HTML, error) {
+	mdBytes, err := fs.ReadFile(fsys, filePath)
 	if err != nil {
 		return "", err
 	}
@@ -121,12 +201,12 @@ func RenderMarkdown(filePath string) (template.HTML, error) {
 	}
 
 	var buf bytes.Buffer
-	err = markdown.Convert(remainingMd, &buf)
-	if err != nil {
-		panic(err)
+	if err := markdown.Convert(remainingMd, &buf); err != nil {
+		return "", err
 	}
 	return template.HTML(buf.String()), nil
 }
+
 func CleanTitle(filename string) string {
 	// Remove the extension (.md) if present
 	title := strings.TrimSuffix(filename, filepath.Ext(filename))
@@ -140,18 +220,21 @@ func CleanTitle(filename string) string {
 	return title
 }
 
-func loadPostsFromDirectory(pattern string) ([]PostData, error) {
-	files, err := filepath.Glob(pattern)
+func loadPostsFromDirectory(site Site, pattern string, opts LoadOptions) ([]PostData, error) {
+	files, err := fs.Glob(site.ContentFS, pattern)
 	if err != nil {
 		return nil, err
 	}
 
 	var posts []PostData
 	for _, file := range files {
-		post, err := LoadPostFromFile(file)
+		post, err := LoadPostFromFile(site, file, opts)
 		if err != nil {
 			return nil, err
 		}
+		if !opts.IncludeDrafts && !post.Published {
+			continue
+		}
 		posts = append(posts, post)
 	}
 
@@ -164,42 +247,216 @@ func loadPostsFromDirectory(pattern string) ([]PostData, error) {
 }
 
 // LoadBlogPosts loads the blog posts from Markdown files and sorts them by date.
-func LoadBlogPosts() ([]PostData, error) {
-	return loadPostsFromDirectory("posts/*.md")
+func LoadBlogPosts(site Site, opts LoadOptions) ([]PostData, error) {
+	return loadPostsFromDirectory(site, "posts/*.md", opts)
 }
 
 // LoadThoughtsPosts loads thoughts blog posts from Markdown files and sorts them by date.
-func LoadThoughtsPosts() ([]PostData, error) {
-	return loadPostsFromDirectory("thoughts/*.md")
+func LoadThoughtsPosts(site Site, opts LoadOptions) ([]PostData, error) {
+	return loadPostsFromDirectory(site, "thoughts/*.md", opts)
+}
+
+// TagIndex maps a tag to the posts carrying it, sorted by date (latest first).
+type TagIndex map[string][]PostData
+
+// TagSlug normalizes tag into the URL- and filesystem-safe form used for
+// /tags/ routes, links, and generated paths: lowercased, with runs of
+// anything other than letters and digits collapsed to a single hyphen.
+// The tag's original text is still used for display (see TagCount).
+func TagSlug(tag string) string {
+	var b strings.Builder
+	dash := true // suppress a leading hyphen
+	for _, r := range strings.ToLower(tag) {
+		switch {
+		case r >= 'a' && r <= 'z' || r >= '0' && r <= '9':
+			b.WriteRune(r)
+			dash = false
+		case !dash:
+			b.WriteByte('-')
+			dash = true
+		}
+	}
+	return strings.TrimSuffix(b.String(), "-")
+}
+
+// tagBySlug finds the tag in idx whose TagSlug matches slug.
+func tagBySlug(idx TagIndex, slug string) (string, bool) {
+	for tag := range idx {
+		if TagSlug(tag) == slug {
+			return tag, true
+		}
+	}
+	return "", false
+}
+
+// BuildTagIndex groups posts by tag.
+func BuildTagIndex(posts []PostData) TagIndex {
+	idx := TagIndex{}
+	for _, post := range posts {
+		for _, tag := range post.Tags {
+			idx[tag] = append(idx[tag], post)
+		}
+	}
+	for _, posts := range idx {
+		sort.Slice(posts, func(i, j int) bool {
+			return posts[i].Date.After(posts[j].Date)
+		})
+	}
+	return idx
+}
+
+// LoadTagIndex loads every post from both the blog and thoughts
+// directories and indexes them by tag, so handlers and the generator can
+// share one index instead of recomputing it separately.
+func LoadTagIndex(site Site, opts LoadOptions) (TagIndex, error) {
+	posts, err := LoadBlogPosts(site, opts)
+	if err != nil {
+		return nil, err
+	}
+	thoughts, err := LoadThoughtsPosts(site, opts)
+	if err != nil {
+		return nil, err
+	}
+	return BuildTagIndex(append(append([]PostData{}, posts...), thoughts...)), nil
 }
 
 func main() {
 	// Check if we should generate static files instead of running a server
 	if len(os.Args) > 1 && os.Args[1] == "--generate" {
-		if err := GenerateStaticSite("public"); err != nil {
+		opts := DefaultLoadOptions()
+		for _, arg := range os.Args[2:] {
+			if arg == "--drafts" {
+				opts.IncludeDrafts = true
+			}
+		}
+		if err := GenerateStaticSite("public", opts, currentSite); err != nil {
 			log.Fatal(err)
 		}
 		fmt.Println("Static site generated successfully!")
 		return
 	}
 
-	http.HandleFunc("/", HomeHandler)
-	http.HandleFunc("/about", AboutHandler)
-	http.HandleFunc("/thoughts", ThoughtsHandler)
-	http.HandleFunc("/post/", PostHandler)
+	if len(os.Args) > 1 && os.Args[1] == "--dev" {
+		cfg := dev.Config{
+			Addr:  ":8090",
+			Watch: []string{"posts", "thoughts", "nav", "templates"},
+			Build: buildSiteHandler,
+		}
+		if err := dev.Serve(cfg); err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+
+	if len(os.Args) > 1 && os.Args[1] == "cspgenerator" {
+		tmpDir, err := os.MkdirTemp("", "goweb-cspgen-*")
+		if err != nil {
+			log.Fatal(err)
+		}
+		defer os.RemoveAll(tmpDir)
+		if err := GenerateStaticSite(tmpDir, DefaultLoadOptions(), currentSite); err != nil {
+			log.Fatal(err)
+		}
+		headers, err := os.ReadFile(filepath.Join(tmpDir, "_headers"))
+		if err != nil {
+			log.Fatal(err)
+		}
+		fmt.Print(string(headers))
+		return
+	}
+
+	handler, err := buildSiteHandler()
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	if _, err := os.Stat("static"); err == nil {
+		staticOutDir := filepath.Join(os.TempDir(), "goweb-static-assets")
+		manifest, err := builder.Build("static", staticOutDir)
+		if err != nil {
+			log.Fatal(err)
+		}
+		runtimeManifest = manifest
+
+		mux := http.NewServeMux()
+		mux.Handle("/static/", http.StripPrefix("/static/", http.FileServer(http.Dir(staticOutDir))))
+		mux.Handle("/", handler)
+		handler = mux
+	}
+
 	fmt.Println("Server is running...")
-	log.Fatal(http.ListenAndServe(":8090", nil))
+	log.Fatal(http.ListenAndServe(":8090", cspMiddleware(handler)))
+}
+
+// cspMiddleware buffers each response, rewrites any static asset
+// references to their content-hashed paths, and sets a
+// Content-Security-Policy header keyed by the asset manifest and any
+// inline <script>/<style> blocks the response contains.
+func cspMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var buf bytes.Buffer
+		rec := &mockResponseWriter{buf: &buf}
+		next.ServeHTTP(rec, r)
+
+		body := rec.buf.Bytes()
+		var csp builder.CSP
+		if len(runtimeManifest) > 0 && strings.Contains(rec.Header().Get("Content-Type"), "html") {
+			if rewritten, pageCSP, err := builder.RewriteHTML(body, runtimeManifest, "/static/"); err == nil {
+				body = rewritten
+				csp = pageCSP
+				// The rewrite changed the served bytes, so any validator the
+				// handler already set (see setCacheHeaders) is now stale.
+				if rec.Header().Get("ETag") != "" {
+					rec.Header().Set("ETag", etagFor(body))
+				}
+			}
+		}
+
+		for k, v := range rec.Header() {
+			w.Header()[k] = v
+		}
+		w.Header().Set("Content-Security-Policy", csp.Header())
+		if rec.status == 0 {
+			rec.status = http.StatusOK
+		}
+		w.WriteHeader(rec.status)
+		w.Write(body)
+	})
+}
+
+// buildSiteHandler wires up the same routes used by the production
+// server, for the in-memory dev server to rebuild on every change.
+func buildSiteHandler() (http.Handler, error) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/", HomeHandler)
+	mux.HandleFunc("/about", AboutHandler)
+	mux.HandleFunc("/thoughts", ThoughtsHandler)
+	mux.HandleFunc("/post/", PostHandler)
+	mux.HandleFunc("/feed.atom", FeedHandler)
+	mux.HandleFunc("/thoughts.atom", ThoughtsFeedHandler)
+	mux.HandleFunc("/all.atom", AllFeedHandler)
+	mux.HandleFunc("/sitemap.xml", SitemapHandler)
+	mux.HandleFunc("/robots.txt", RobotsHandler)
+	// Serves currentSite.StaticFS directly (un-hashed). main() overrides
+	// this with a content-hashed file server whenever a disk static/
+	// directory exists to run the CSP asset pipeline against; this is the
+	// fallback for --dev and for an embedded binary with no disk to hash.
+	mux.Handle("/static/", http.StripPrefix("/static/", http.FileServerFS(currentSite.StaticFS)))
+	mux.HandleFunc("/tags/", TagsHandler)
+	return mux, nil
 }
 
-// GenerateStaticSite generates static HTML files for all pages
-func GenerateStaticSite(outputDir string) error {
+// GenerateStaticSite generates static HTML files for all pages. opts
+// controls whether drafts and future-dated posts are included; callers
+// that want production output should exclude them.
+func GenerateStaticSite(outputDir string, opts LoadOptions, site Site) error {
 	if err := os.MkdirAll(outputDir, 0755); err != nil {
 		return err
 	}
 
 	// Generate index.html (home page)
 	if err := generatePage(outputDir, "index.html", func(w http.ResponseWriter) error {
-		HomeHandler(w, &http.Request{})
+		renderHome(w, site, opts)
 		return nil
 	}); err != nil {
 		return err
@@ -207,7 +464,7 @@ func GenerateStaticSite(outputDir string) error {
 
 	// Generate about.html
 	if err := generatePage(outputDir, "about.html", func(w http.ResponseWriter) error {
-		AboutHandler(w, &http.Request{})
+		renderAbout(w, site)
 		return nil
 	}); err != nil {
 		return err
@@ -215,56 +472,171 @@ func GenerateStaticSite(outputDir string) error {
 
 	// Generate thoughts.html
 	if err := generatePage(outputDir, "thoughts.html", func(w http.ResponseWriter) error {
-		ThoughtsHandler(w, &http.Request{})
+		renderThoughts(w, site, opts)
 		return nil
 	}); err != nil {
 		return err
 	}
 	// Generate post pages from posts directory
-	files, err := filepath.Glob("posts/*.md")
+	files, err := fs.Glob(site.ContentFS, "posts/*.md")
 	if err != nil {
 		return err
 	}
 
 	for _, file := range files {
-		slug := strings.TrimSuffix(filepath.Base(file), filepath.Ext(file))
+		post, err := LoadPostFromFile(site, file, opts)
+		if err != nil {
+			return err
+		}
+		if !opts.IncludeDrafts && !post.Published {
+			continue
+		}
+		slug := strings.TrimSuffix(path.Base(file), path.Ext(file))
 		// Generate as post/slug/index.html for GitHub Pages clean URLs
 		postPath := filepath.Join("post", slug, "index.html")
-		reqURL, _ := url.Parse("/post/" + slug)
-		req := &http.Request{
-			URL: reqURL,
-		}
 		if err := generatePage(outputDir, postPath, func(w http.ResponseWriter) error {
-			PostHandler(w, req)
+			renderPost(w, site, slug, opts)
 			return nil
 		}); err != nil {
 			return err
 		}
 	}
 
+	// Generate feed documents
+	if err := generatePage(outputDir, "feed.atom", func(w http.ResponseWriter) error {
+		renderFeed(w, site, opts)
+		return nil
+	}); err != nil {
+		return err
+	}
+	if err := generatePage(outputDir, "thoughts.atom", func(w http.ResponseWriter) error {
+		renderThoughtsFeed(w, site, opts)
+		return nil
+	}); err != nil {
+		return err
+	}
+	if err := generatePage(outputDir, "all.atom", func(w http.ResponseWriter) error {
+		renderAllFeed(w, site, opts)
+		return nil
+	}); err != nil {
+		return err
+	}
+
+	// Generate sitemap.xml and robots.txt
+	if err := generatePage(outputDir, "sitemap.xml", func(w http.ResponseWriter) error {
+		renderSitemap(w, site, opts)
+		return nil
+	}); err != nil {
+		return err
+	}
+	if err := generatePage(outputDir, "robots.txt", func(w http.ResponseWriter) error {
+		RobotsHandler(w, &http.Request{})
+		return nil
+	}); err != nil {
+		return err
+	}
+
 	// Generate post pages from thoughts directory
-	thoughtsFiles, err := filepath.Glob("thoughts/*.md")
+	thoughtsFiles, err := fs.Glob(site.ContentFS, "thoughts/*.md")
 	if err != nil {
 		return err
 	}
 
 	for _, file := range thoughtsFiles {
-		slug := strings.TrimSuffix(filepath.Base(file), filepath.Ext(file))
+		post, err := LoadPostFromFile(site, file, opts)
+		if err != nil {
+			return err
+		}
+		if !opts.IncludeDrafts && !post.Published {
+			continue
+		}
+		slug := strings.TrimSuffix(path.Base(file), path.Ext(file))
 		// Generate as post/slug/index.html for GitHub Pages clean URLs
 		postPath := filepath.Join("post", slug, "index.html")
-		reqURL, _ := url.Parse("/post/" + slug)
-		req := &http.Request{
-			URL: reqURL,
-		}
 		if err := generatePage(outputDir, postPath, func(w http.ResponseWriter) error {
-			PostHandler(w, req)
+			renderPost(w, site, slug, opts)
+			return nil
+		}); err != nil {
+			return err
+		}
+	}
+
+	// Generate taxonomy pages: the tag index, each tag's post listing, and
+	// each tag's Atom feed.
+	tagIndex, err := LoadTagIndex(site, opts)
+	if err != nil {
+		return err
+	}
+	if err := generatePage(outputDir, filepath.Join("tags", "index.html"), func(w http.ResponseWriter) error {
+		renderTagsIndex(w, site, opts)
+		return nil
+	}); err != nil {
+		return err
+	}
+	for tag := range tagIndex {
+		tag := tag
+		slug := TagSlug(tag)
+		tagPath := filepath.Join("tags", slug, "index.html")
+		if err := generatePage(outputDir, tagPath, func(w http.ResponseWriter) error {
+			renderTag(w, site, tag, opts)
+			return nil
+		}); err != nil {
+			return err
+		}
+
+		feedPath := filepath.Join("tags", slug+".atom")
+		if err := generatePage(outputDir, feedPath, func(w http.ResponseWriter) error {
+			renderTagFeed(w, site, tag, opts)
 			return nil
 		}); err != nil {
 			return err
 		}
 	}
 
-	return nil
+	return applyAssetPipeline(outputDir)
+}
+
+// applyAssetPipeline content-hashes static/ into outputDir/static (if
+// static/ exists), rewrites asset references in every generated HTML
+// page to their hashed paths, and writes the resulting
+// Content-Security-Policy to a Netlify/CloudFront-style _headers file.
+func applyAssetPipeline(outputDir string) error {
+	if _, err := os.Stat("static"); err != nil {
+		return nil
+	}
+
+	manifest, err := builder.Build("static", filepath.Join(outputDir, "static"))
+	if err != nil {
+		return err
+	}
+
+	var csp builder.CSP
+	err = filepath.Walk(outputDir, func(path string, info os.FileInfo, err error) error {
+		if err != nil || info.IsDir() || filepath.Ext(path) != ".html" {
+			return err
+		}
+		html, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		rewritten, pageCSP, err := builder.RewriteHTML(html, manifest, "/static/")
+		if err != nil {
+			return err
+		}
+		csp.ScriptHashes = append(csp.ScriptHashes, pageCSP.ScriptHashes...)
+		csp.StyleHashes = append(csp.StyleHashes, pageCSP.StyleHashes...)
+		return os.WriteFile(path, rewritten, info.Mode())
+	})
+	if err != nil {
+		return err
+	}
+
+	headersFile, err := os.Create(filepath.Join(outputDir, "_headers"))
+	if err != nil {
+		return err
+	}
+	defer headersFile.Close()
+	return builder.WriteHeadersFile(headersFile, csp.Header())
 }
 
 // generatePage generates a single HTML page by executing a handler
@@ -311,39 +683,104 @@ type responseWriter struct {
 	http.ResponseWriter
 }
 
-func PostHandler(w http.ResponseWriter, r *http.Request) {
+// etagFor returns the strong ETag value for content, quotes included.
+func etagFor(content []byte) string {
+	sum := sha256.Sum256(content)
+	return `"` + hex.EncodeToString(sum[:8]) + `"`
+}
+
+// setCacheHeaders sets Last-Modified to modTime and a strong ETag derived
+// from content, so browsers and CDNs can skip re-fetching pages that
+// haven't changed.
+func setCacheHeaders(w http.ResponseWriter, modTime time.Time, content []byte) {
+	w.Header().Set("Last-Modified", modTime.UTC().Format(http.TimeFormat))
+	w.Header().Set("ETag", etagFor(content))
+}
+
+// templateFuncs are available to every template parsed via newTemplate.
+var templateFuncs = template.FuncMap{
+	"tagSlug": TagSlug,
+}
+
+// newTemplate parses files from site.TemplateFS with templateFuncs
+// available, rooted at files[0] so Execute (without ExecuteTemplate)
+// renders that file's content, matching template.ParseFiles' convention.
+func newTemplate(site Site, files ...string) *template.Template {
+	return template.Must(template.New(files[0]).Funcs(templateFuncs).ParseFS(site.TemplateFS, files...))
+}
+
+// writeTemplate executes tmpl into a buffer so the response can carry
+// Last-Modified/ETag headers derived from its actual content before
+// anything is written to w.
+func writeTemplate(w http.ResponseWriter, tmpl *template.Template, data any, modTime time.Time) error {
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return err
+	}
+	setCacheHeaders(w, modTime, buf.Bytes())
 	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	_, err := w.Write(buf.Bytes())
+	return err
+}
+
+// latestDate returns the most recent Date among posts, falling back to
+// siteConfig.StartDate when posts is empty.
+func latestDate(posts []PostData) time.Time {
+	latest := siteConfig.StartDate
+	for _, post := range posts {
+		if post.Date.After(latest) {
+			latest = post.Date
+		}
+	}
+	return latest
+}
+
+func PostHandler(w http.ResponseWriter, r *http.Request) {
 	slug := r.URL.Path[len("/post/"):]
-	post, err := LoadPost(slug)
+	renderPost(w, currentSite, slug, loadOptionsForRequest(r))
+}
+
+func renderPost(w http.ResponseWriter, site Site, slug string, opts LoadOptions) {
+	post, err := LoadPost(site, slug, opts)
 	if err != nil {
 		http.Error(w, "Post not found", http.StatusNotFound)
 		return
 	}
 
-	tmpl := template.Must(template.ParseFiles(filepath.Join("templates", "base.gohtml"), filepath.Join("templates", "post.gohtml")))
-	if err := tmpl.Execute(w, post); err != nil {
+	tmpl := newTemplate(site, "base.gohtml", "post.gohtml")
+	if err := writeTemplate(w, tmpl, post, post.Date); err != nil {
 		http.Error(w, "Error executing template", http.StatusInternalServerError)
 		return
 	}
 }
 
-func LoadPost(slug string) (PostData, error) {
+// LoadPost loads a single post by slug, checking posts/ then thoughts/ in
+// site.ContentFS, and fails if it's a draft or future-dated post that
+// opts doesn't include.
+func LoadPost(site Site, slug string, opts LoadOptions) (PostData, error) {
 	// Try to find the post in posts directory first
-	file := filepath.Join("posts", slug+".md")
-	if _, err := os.Stat(file); os.IsNotExist(err) {
+	file := path.Join("posts", slug+".md")
+	if _, err := fs.Stat(site.ContentFS, file); err != nil {
 		// If not found, try thoughts directory
-		file = filepath.Join("thoughts", slug+".md")
-		if _, err := os.Stat(file); os.IsNotExist(err) {
+		file = path.Join("thoughts", slug+".md")
+		if _, err := fs.Stat(site.ContentFS, file); err != nil {
 			return PostData{}, err
 		}
 	}
 
 	// Load post using the helper function that parses frontmatter
-	return LoadPostFromFile(file)
+	post, err := LoadPostFromFile(site, file, opts)
+	if err != nil {
+		return PostData{}, err
+	}
+	if !opts.IncludeDrafts && !post.Published {
+		return PostData{}, fmt.Errorf("post %q is not published", slug)
+	}
+	return post, nil
 }
 
 // postsHandler is a generic handler for rendering pages with blog posts.
-func postsHandler(w http.ResponseWriter, loadPosts func() ([]PostData, error), title, templateName string) {
+func postsHandler(w http.ResponseWriter, site Site, loadPosts func() ([]PostData, error), title, templateName string) {
 	posts, err := loadPosts()
 	if err != nil {
 		http.Error(w, "Error loading posts", http.StatusInternalServerError)
@@ -356,11 +793,8 @@ func postsHandler(w http.ResponseWriter, loadPosts func() ([]PostData, error), t
 		Posts: posts,
 	}
 
-	tmpl := template.Must(template.ParseFiles(
-		filepath.Join("templates", "base.gohtml"),
-		filepath.Join("templates", templateName),
-	))
-	if err := tmpl.Execute(w, data); err != nil {
+	tmpl := newTemplate(site, "base.gohtml", templateName)
+	if err := writeTemplate(w, tmpl, data, latestDate(posts)); err != nil {
 		http.Error(w, "Error executing template", http.StatusInternalServerError)
 		log.Printf("Error executing template %s: %v", templateName, err)
 		return
@@ -369,17 +803,355 @@ func postsHandler(w http.ResponseWriter, loadPosts func() ([]PostData, error), t
 
 // HomeHandler renders the home page with blog posts.
 func HomeHandler(w http.ResponseWriter, r *http.Request) {
-	postsHandler(w, LoadBlogPosts, "Home", "home.gohtml")
+	renderHome(w, currentSite, loadOptionsForRequest(r))
+}
+
+func renderHome(w http.ResponseWriter, site Site, opts LoadOptions) {
+	postsHandler(w, site, func() ([]PostData, error) { return LoadBlogPosts(site, opts) }, "Home", "home.gohtml")
 }
 
 // ThoughtsHandler renders the thoughts blog posts page.
 func ThoughtsHandler(w http.ResponseWriter, r *http.Request) {
-	postsHandler(w, LoadThoughtsPosts, "Thoughts", "thoughts.gohtml")
+	renderThoughts(w, currentSite, loadOptionsForRequest(r))
+}
+
+func renderThoughts(w http.ResponseWriter, site Site, opts LoadOptions) {
+	postsHandler(w, site, func() ([]PostData, error) { return LoadThoughtsPosts(site, opts) }, "Thoughts", "thoughts.gohtml")
+}
+
+// TagsHandler dispatches /tags/ requests to the taxonomy index, a single
+// tag's post listing, or that tag's Atom feed. The path segment after
+// /tags/ is a TagSlug, not the tag's literal text, so it's resolved back
+// to the tag via the current index before rendering.
+func TagsHandler(w http.ResponseWriter, r *http.Request) {
+	opts := loadOptionsForRequest(r)
+	rest := strings.TrimPrefix(r.URL.Path, "/tags/")
+	if rest == "" {
+		renderTagsIndex(w, currentSite, opts)
+		return
+	}
+
+	isFeed := strings.HasSuffix(rest, ".atom")
+	slug := strings.TrimSuffix(strings.TrimSuffix(rest, ".atom"), "/")
+
+	idx, err := LoadTagIndex(currentSite, opts)
+	if err != nil {
+		http.Error(w, "Error loading tags", http.StatusInternalServerError)
+		log.Printf("Error loading tag index: %v", err)
+		return
+	}
+	tag, ok := tagBySlug(idx, slug)
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+
+	if isFeed {
+		renderTagFeed(w, currentSite, tag, opts)
+		return
+	}
+	renderTag(w, currentSite, tag, opts)
+}
+
+// TagCount pairs a tag with its slug and how many posts carry it.
+type TagCount struct {
+	Tag   string
+	Slug  string
+	Count int
+}
+
+// renderTagsIndex renders /tags/, listing every tag with its post count.
+func renderTagsIndex(w http.ResponseWriter, site Site, opts LoadOptions) {
+	idx, err := LoadTagIndex(site, opts)
+	if err != nil {
+		http.Error(w, "Error loading tags", http.StatusInternalServerError)
+		log.Printf("Error loading tag index: %v", err)
+		return
+	}
+
+	var tags []TagCount
+	var all []PostData
+	for tag, posts := range idx {
+		tags = append(tags, TagCount{Tag: tag, Slug: TagSlug(tag), Count: len(posts)})
+		all = append(all, posts...)
+	}
+	sort.Slice(tags, func(i, j int) bool { return tags[i].Tag < tags[j].Tag })
+
+	data := struct {
+		Title string
+		Tags  []TagCount
+	}{Title: "Tags", Tags: tags}
+
+	tmpl := newTemplate(site, "base.gohtml", "tags.gohtml")
+	if err := writeTemplate(w, tmpl, data, latestDate(all)); err != nil {
+		http.Error(w, "Error executing template", http.StatusInternalServerError)
+		return
+	}
+}
+
+// renderTag renders /tags/{tag}/, listing the posts carrying tag.
+func renderTag(w http.ResponseWriter, site Site, tag string, opts LoadOptions) {
+	idx, err := LoadTagIndex(site, opts)
+	if err != nil {
+		http.Error(w, "Error loading tags", http.StatusInternalServerError)
+		log.Printf("Error loading tag index: %v", err)
+		return
+	}
+
+	data := TemplateData{Title: "Tag: " + tag, Posts: idx[tag]}
+	tmpl := newTemplate(site, "base.gohtml", "tag.gohtml")
+	if err := writeTemplate(w, tmpl, data, latestDate(idx[tag])); err != nil {
+		http.Error(w, "Error executing template", http.StatusInternalServerError)
+		return
+	}
+}
+
+// renderTagFeed serves the Atom feed for a single tag.
+func renderTagFeed(w http.ResponseWriter, site Site, tag string, opts LoadOptions) {
+	idx, err := LoadTagIndex(site, opts)
+	if err != nil {
+		http.Error(w, "Error loading tags", http.StatusInternalServerError)
+		log.Printf("Error loading tag index: %v", err)
+		return
+	}
+	slug := TagSlug(tag)
+	writeFeed(w, idx[tag], "Tag: "+tag, "/tags/"+slug+"/", "/tags/"+slug+".atom")
+}
+
+// SiteURL describes a single <url> entry in the sitemap.
+type SiteURL struct {
+	Loc        string
+	LastMod    time.Time
+	ChangeFreq string
+	Priority   string
+}
+
+// SiteMap enumerates every URL the site serves, shared by the static
+// sitemap.xml generator and the runtime /sitemap.xml handler.
+func SiteMap(site Site, opts LoadOptions) ([]SiteURL, error) {
+	now := time.Now()
+	urls := []SiteURL{
+		{Loc: siteConfig.BaseURL + "/", LastMod: now, ChangeFreq: "daily", Priority: "1.0"},
+		{Loc: siteConfig.BaseURL + "/about", LastMod: now, ChangeFreq: "monthly", Priority: "0.5"},
+		{Loc: siteConfig.BaseURL + "/thoughts", LastMod: now, ChangeFreq: "daily", Priority: "0.8"},
+	}
+
+	posts, err := LoadBlogPosts(site, opts)
+	if err != nil {
+		return nil, err
+	}
+	for _, post := range posts {
+		urls = append(urls, SiteURL{
+			Loc:        siteConfig.BaseURL + "/post/" + post.Slug + "/",
+			LastMod:    post.Date,
+			ChangeFreq: "monthly",
+			Priority:   "0.7",
+		})
+	}
+
+	thoughts, err := LoadThoughtsPosts(site, opts)
+	if err != nil {
+		return nil, err
+	}
+	for _, post := range thoughts {
+		urls = append(urls, SiteURL{
+			Loc:        siteConfig.BaseURL + "/post/" + post.Slug + "/",
+			LastMod:    post.Date,
+			ChangeFreq: "monthly",
+			Priority:   "0.7",
+		})
+	}
+
+	tagIndex := BuildTagIndex(append(append([]PostData{}, posts...), thoughts...))
+	urls = append(urls, SiteURL{Loc: siteConfig.BaseURL + "/tags/", LastMod: now, ChangeFreq: "weekly", Priority: "0.4"})
+	for tag, tagged := range tagIndex {
+		lastMod := now
+		if len(tagged) > 0 {
+			lastMod = tagged[0].Date
+		}
+		urls = append(urls, SiteURL{
+			Loc:        siteConfig.BaseURL + "/tags/" + TagSlug(tag) + "/",
+			LastMod:    lastMod,
+			ChangeFreq: "weekly",
+			Priority:   "0.4",
+		})
+	}
+
+	return urls, nil
+}
+
+type sitemapURLSet struct {
+	XMLName xml.Name     `xml:"http://www.sitemaps.org/schemas/sitemap/0.9 urlset"`
+	URLs    []sitemapURL `xml:"url"`
+}
+
+type sitemapURL struct {
+	Loc        string `xml:"loc"`
+	LastMod    string `xml:"lastmod,omitempty"`
+	ChangeFreq string `xml:"changefreq,omitempty"`
+	Priority   string `xml:"priority,omitempty"`
+}
+
+// WriteSitemap renders urls as a sitemaps.org-compliant sitemap.xml.
+func WriteSitemap(urls []SiteURL, w io.Writer) error {
+	doc := sitemapURLSet{}
+	for _, u := range urls {
+		doc.URLs = append(doc.URLs, sitemapURL{
+			Loc:        u.Loc,
+			LastMod:    u.LastMod.Format("2006-01-02"),
+			ChangeFreq: u.ChangeFreq,
+			Priority:   u.Priority,
+		})
+	}
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	return enc.Encode(doc)
+}
+
+// SitemapHandler serves sitemap.xml at runtime.
+func SitemapHandler(w http.ResponseWriter, r *http.Request) {
+	renderSitemap(w, currentSite, loadOptionsForRequest(r))
+}
+
+func renderSitemap(w http.ResponseWriter, site Site, opts LoadOptions) {
+	urls, err := SiteMap(site, opts)
+	if err != nil {
+		http.Error(w, "Error building sitemap", http.StatusInternalServerError)
+		log.Printf("Error building sitemap: %v", err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/xml; charset=utf-8")
+	if err := WriteSitemap(urls, w); err != nil {
+		http.Error(w, "Error writing sitemap", http.StatusInternalServerError)
+		log.Printf("Error writing sitemap: %v", err)
+	}
+}
+
+// RobotsHandler serves robots.txt, pointing crawlers at the sitemap.
+func RobotsHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	fmt.Fprintf(w, "User-agent: *\nAllow: /\nSitemap: %s/sitemap.xml\n", siteConfig.BaseURL)
+}
+
+// buildFeed converts a list of posts into a feed.FeedContent, deriving
+// each entry's tag URI from siteConfig and the post's own date.
+func buildFeed(posts []PostData, title, htmlPath, feedPath string) feed.FeedContent {
+	fc := feed.FeedContent{
+		Title:   title,
+		Link:    siteConfig.BaseURL + htmlPath,
+		Self:    siteConfig.BaseURL + feedPath,
+		Author:  siteConfig.AuthorName,
+		Updated: siteConfig.StartDate,
+	}
+	for _, post := range posts {
+		if post.Date.After(fc.Updated) {
+			fc.Updated = post.Date
+		}
+		link := siteConfig.BaseURL + "/post/" + post.Slug + "/"
+		fc.Entries = append(fc.Entries, feed.FeedEntry{
+			Title:     post.Title,
+			Link:      link,
+			ID:        feed.MakeTagURI(siteConfig.Domain, post.Date.Format("2006-01-02"), "post/"+post.Slug),
+			Published: post.Date,
+			Updated:   post.Date,
+			Summary:   firstParagraph(string(post.Content)),
+			Content:   string(post.Content),
+		})
+	}
+	return fc
+}
+
+// firstParagraph returns the first <p>...</p> block of an HTML fragment,
+// falling back to the whole fragment if none is found.
+func firstParagraph(html string) string {
+	start := strings.Index(html, "<p>")
+	if start == -1 {
+		return html
+	}
+	end := strings.Index(html[start:], "</p>")
+	if end == -1 {
+		return html
+	}
+	return html[start : start+end+len("</p>")]
+}
+
+// writeFeed writes posts as an Atom feed to w, setting the appropriate
+// content type.
+func writeFeed(w http.ResponseWriter, posts []PostData, title, htmlPath, feedPath string) {
+	w.Header().Set("Content-Type", "application/atom+xml; charset=utf-8")
+	fc := buildFeed(posts, title, htmlPath, feedPath)
+	if err := fc.WriteAtom(w); err != nil {
+		http.Error(w, "Error writing feed", http.StatusInternalServerError)
+		log.Printf("Error writing feed %s: %v", title, err)
+	}
+}
+
+// FeedHandler serves the Atom feed for blog posts.
+func FeedHandler(w http.ResponseWriter, r *http.Request) {
+	renderFeed(w, currentSite, loadOptionsForRequest(r))
+}
+
+func renderFeed(w http.ResponseWriter, site Site, opts LoadOptions) {
+	posts, err := LoadBlogPosts(site, opts)
+	if err != nil {
+		http.Error(w, "Error loading posts", http.StatusInternalServerError)
+		log.Printf("Error loading posts for feed: %v", err)
+		return
+	}
+	writeFeed(w, posts, "Posts", "/", "/feed.atom")
+}
+
+// ThoughtsFeedHandler serves the Atom feed for thoughts posts.
+func ThoughtsFeedHandler(w http.ResponseWriter, r *http.Request) {
+	renderThoughtsFeed(w, currentSite, loadOptionsForRequest(r))
+}
+
+func renderThoughtsFeed(w http.ResponseWriter, site Site, opts LoadOptions) {
+	posts, err := LoadThoughtsPosts(site, opts)
+	if err != nil {
+		http.Error(w, "Error loading posts", http.StatusInternalServerError)
+		log.Printf("Error loading posts for thoughts feed: %v", err)
+		return
+	}
+	writeFeed(w, posts, "Thoughts", "/thoughts", "/thoughts.atom")
+}
+
+// AllFeedHandler serves a combined Atom feed of posts and thoughts.
+func AllFeedHandler(w http.ResponseWriter, r *http.Request) {
+	renderAllFeed(w, currentSite, loadOptionsForRequest(r))
+}
+
+func renderAllFeed(w http.ResponseWriter, site Site, opts LoadOptions) {
+	posts, err := LoadBlogPosts(site, opts)
+	if err != nil {
+		http.Error(w, "Error loading posts", http.StatusInternalServerError)
+		log.Printf("Error loading posts for combined feed: %v", err)
+		return
+	}
+	thoughts, err := LoadThoughtsPosts(site, opts)
+	if err != nil {
+		http.Error(w, "Error loading posts", http.StatusInternalServerError)
+		log.Printf("Error loading posts for combined feed: %v", err)
+		return
+	}
+	all := append(append([]PostData{}, posts...), thoughts...)
+	sort.Slice(all, func(i, j int) bool {
+		return all[i].Date.After(all[j].Date)
+	})
+	writeFeed(w, all, "All", "/", "/all.atom")
 }
 
 // AboutHandler serves the About page.
 func AboutHandler(w http.ResponseWriter, r *http.Request) {
-	content, err := RenderMarkdown("nav/about.md")
+	renderAbout(w, currentSite)
+}
+
+func renderAbout(w http.ResponseWriter, site Site) {
+	content, err := renderMarkdownFS(site.ContentFS, "nav/about.md")
 	if err != nil {
 		http.Error(w, "Error loading about page", http.StatusInternalServerError)
 		return
@@ -391,8 +1163,14 @@ func AboutHandler(w http.ResponseWriter, r *http.Request) {
 		Title:   "About Me",
 		Content: content,
 	}
-	tmpl := template.Must(template.ParseFiles(filepath.Join("templates", "base.gohtml"), filepath.Join("templates", "about.gohtml")))
-	if err := tmpl.Execute(w, data); err != nil {
+
+	modTime := siteConfig.StartDate
+	if info, err := fs.Stat(site.ContentFS, "nav/about.md"); err == nil {
+		modTime = info.ModTime()
+	}
+
+	tmpl := newTemplate(site, "base.gohtml", "about.gohtml")
+	if err := writeTemplate(w, tmpl, data, modTime); err != nil {
 		http.Error(w, "Error executing template", http.StatusInternalServerError)
 		return
 	}
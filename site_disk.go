@@ -0,0 +1,17 @@
+//go:build !embedded
+
+package main
+
+import "os"
+
+// NewSite builds the disk-backed Site: content and templates are read
+// straight from the working directory, so edits on disk are picked up
+// without a rebuild (what --dev relies on). The embedded alternative lives
+// in site_embed.go, built with -tags embedded.
+func NewSite() Site {
+	return Site{
+		ContentFS:  os.DirFS("."),
+		TemplateFS: os.DirFS("templates"),
+		StaticFS:   os.DirFS("static"),
+	}
+}
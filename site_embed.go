@@ -0,0 +1,31 @@
+//go:build embedded
+
+package main
+
+import (
+	"embed"
+	"io/fs"
+)
+
+//go:embed posts thoughts nav templates static
+var embeddedFS embed.FS
+
+// NewSite builds the embedded Site: content, templates, and static assets
+// are baked into the binary at compile time, so the result runs as a
+// single executable with no files on disk. Built with -tags embedded; the
+// default build uses the disk-backed Site in site_disk.go instead.
+func NewSite() Site {
+	templateFS, err := fs.Sub(embeddedFS, "templates")
+	if err != nil {
+		panic(err)
+	}
+	staticFS, err := fs.Sub(embeddedFS, "static")
+	if err != nil {
+		panic(err)
+	}
+	return Site{
+		ContentFS:  embeddedFS,
+		TemplateFS: templateFS,
+		StaticFS:   staticFS,
+	}
+}